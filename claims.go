@@ -1,6 +1,7 @@
 package jwt
 
 import (
+	"encoding/json"
 	"errors"
 	"time"
 )
@@ -14,6 +15,40 @@ var (
 	ErrIssuedInTheFuture = errors.New("token issued in the future")
 )
 
+// WithLeeway returns a TokenValidator that re-evaluates the standard "nbf",
+// "iat" and "exp" claims using d as clock skew tolerance, widening each
+// comparison symmetrically: a token is accepted as long as
+// `now+d >= NotBefore`, `now+d >= IssuedAt` and `now-d <= Expiry`. Apart
+// from `ValidateClaimsV2`, which takes a leeway of its own, this is the only
+// way to apply leeway to a `Verify` call; there is no
+// package-level default, so every call that wants skew tolerance must chain
+// it explicitly, e.g. a stricter d for token exchange or a looser one for
+// casual reads:
+//
+//  Verify(alg, key, token, WithLeeway(0))            // exact, no skew
+//  Verify(alg, key, token, WithLeeway(30*time.Second))
+//
+// WithLeeway has no effect on `MaxAge` or `MaxAgeMap`, which always stamp
+// "exp" and "iat" using the exact `Clock()` time with no skew applied;
+// leeway is only ever consulted later, when the resulting token is verified.
+//
+// Like every other TokenValidator in this package, WithLeeway only ever
+// overrides err when err is nil or one of its own time sentinels
+// (ErrExpired, ErrNotValidYet, ErrIssuedInTheFuture); any other err (e.g. a
+// rejection from an Expected validator chained earlier) is propagated
+// unchanged, so WithLeeway can never paper over a failure it didn't cause.
+func WithLeeway(d time.Duration) TokenValidatorFunc {
+	return func(token []byte, standardClaims Claims, err error) error {
+		switch err {
+		case nil, ErrExpired, ErrNotValidYet, ErrIssuedInTheFuture:
+		default:
+			return err
+		}
+
+		return validateClaims(Clock(), standardClaims, d)
+	}
+}
+
 // Claims holds the standard JWT claims (payload fields).
 // It can be used to validate the JWT and to sign it.
 // It completes the `SignOption` interface.
@@ -52,29 +87,93 @@ type Claims struct {
 	// values that uniquely identify the intended recipients of this JWT. In other words, when this
 	// claim is present, the party reading the data in this JWT must find itself in the aud claim or
 	// disregard the data contained in the JWT. As in the case of the iss and sub claims, this claim
-	// is application specific.
-	Audience []string `json:"aud,omitempty"`
+	// is application specific. Accepts either a single string or an array of
+	// strings on the wire; see `ClaimStrings`.
+	Audience ClaimStrings `json:"aud,omitempty"`
+	// A string value used to associate a client session with a token and to
+	// mitigate replay attacks. Its value is typically a case-sensitive string
+	// chosen by the relying party, copied here unmodified, and compared
+	// against by the `Expected` TokenValidator when verifying OIDC ID tokens.
+	Nonce string `json:"nonce,omitempty"`
+	// The party to which the token was issued, as opposed to the intended
+	// recipients in the aud claim. An OIDC ID token only needs this claim
+	// when `Audience` lists more than one value, in which case it must equal
+	// the relying party's client ID.
+	AuthorizedParty string `json:"azp,omitempty"`
+
+	// extra holds the custom claims `TopLevelClaims` mirrors onto the
+	// top-level JSON object at Sign time. It is unexported because it is
+	// only ever populated through that SignOption, never set directly.
+	extra map[string]interface{}
+}
+
+// MarshalJSON implements the json.Marshaler interface. It marshals the
+// registered claim fields the normal way, then merges in any custom claims
+// `TopLevelClaims` mirrored onto c, so they end up alongside "iss", "exp"
+// and friends in the signed payload instead of nested under a sub-object.
+func (c Claims) MarshalJSON() ([]byte, error) {
+	type alias Claims
+
+	base, err := json.Marshal(alias(c))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(c.extra) == 0 {
+		return base, nil
+	}
+
+	extra, err := json.Marshal(c.extra)
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeJSONObjects(base, extra), nil
 }
 
 // See TokenValidator and its implementations
 // for further validation options.
-func validateClaims(t time.Time, claims Claims) error {
-	now := t.Round(time.Second).Unix()
-
+//
+// leeway widens each comparison symmetrically, see the `WithLeeway`
+// TokenValidator for how callers control it.
+func validateClaims(t time.Time, claims Claims, leeway time.Duration) error {
+	var nbf, iat, exp *NumericDate
 	if claims.NotBefore > 0 {
-		if now < claims.NotBefore {
+		nbf = NewNumericDate(time.Unix(claims.NotBefore, 0))
+	}
+	if claims.IssuedAt > 0 {
+		iat = NewNumericDate(time.Unix(claims.IssuedAt, 0))
+	}
+	if claims.Expiry > 0 {
+		exp = NewNumericDate(time.Unix(claims.Expiry, 0))
+	}
+
+	return validateNumericClaims(t.Round(time.Second), nbf, iat, exp, leeway)
+}
+
+// validateNumericClaims is the core time-based validation shared by
+// `validateClaims` (Claims, int64 seconds) and `validateClaimsV2` (ClaimsV2,
+// NumericDate). A nil pointer means the corresponding claim was absent and
+// is skipped, matching the "> 0" guards the int64-based checks used to do
+// inline. t is compared as given; `validateClaims` rounds it to whole
+// seconds first, `validateClaimsV2` keeps its sub-second part.
+func validateNumericClaims(t time.Time, nbf, iat, exp *NumericDate, leeway time.Duration) error {
+	now := t
+
+	if nbf != nil {
+		if now.Add(leeway).Before(nbf.Time) {
 			return ErrNotValidYet
 		}
 	}
 
-	if claims.IssuedAt > 0 {
-		if now < claims.IssuedAt {
+	if iat != nil {
+		if now.Add(leeway).Before(iat.Time) {
 			return ErrIssuedInTheFuture
 		}
 	}
 
-	if claims.Expiry > 0 {
-		if now > claims.Expiry {
+	if exp != nil {
+		if now.Add(-leeway).After(exp.Time) {
 			return ErrExpired
 		}
 	}
@@ -111,6 +210,14 @@ func (c Claims) ApplyClaims(dest *Claims) {
 	if v := c.Audience; len(v) > 0 {
 		dest.Audience = v
 	}
+
+	if v := c.Nonce; v != "" {
+		dest.Nonce = v
+	}
+
+	if v := c.AuthorizedParty; v != "" {
+		dest.AuthorizedParty = v
+	}
 }
 
 // MaxAge is a SignOption to set the expiration "exp", "iat" JWT standard claims.
@@ -122,6 +229,11 @@ func (c Claims) ApplyClaims(dest *Claims) {
 //
 // See the `Clock` package-level variable to modify
 // the current time function.
+//
+// MaxAge always stamps "exp" and "iat" using the exact current time; it does
+// not apply any leeway. Leeway (see `WithLeeway`) is only ever consulted
+// later, when the resulting token is verified, so it never changes what
+// gets signed, only how tolerant verification is of clock drift.
 func MaxAge(maxAge time.Duration) SignOptionFunc {
 	return func(c *Claims) {
 		if maxAge <= time.Second {
@@ -138,6 +250,10 @@ func MaxAge(maxAge time.Duration) SignOptionFunc {
 // claims := map[string]interface{}{"foo": "bar"}
 // MaxAgeMap(15 * time.Minute, claims)
 // Sign(alg, key, claims)
+//
+// Like `MaxAge`, MaxAgeMap stamps "exp" and "iat" using the exact current
+// time and applies no leeway; `WithLeeway` only widens the comparisons
+// performed when the token is later verified.
 func MaxAgeMap(maxAge time.Duration, claims Map) {
 	if claims == nil {
 		return
@@ -0,0 +1,109 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestValidateClaims_Leeway exercises the boundary the Leeway widening adds
+// to validateClaims: exactly on the boundary must still validate, one
+// second inside it must validate, and one second outside it must fail.
+func TestValidateClaims_Leeway(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	leeway := 5 * time.Second
+
+	tests := []struct {
+		name    string
+		claims  Claims
+		wantErr error
+	}{
+		{
+			name:   "expiry exactly on the leeway boundary is valid",
+			claims: Claims{Expiry: now.Add(-leeway).Unix()},
+		},
+		{
+			name:   "expiry just inside the leeway boundary is valid",
+			claims: Claims{Expiry: now.Add(-leeway + time.Second).Unix()},
+		},
+		{
+			name:    "expiry just outside the leeway boundary is expired",
+			claims:  Claims{Expiry: now.Add(-leeway - time.Second).Unix()},
+			wantErr: ErrExpired,
+		},
+		{
+			name:   "not-before exactly on the leeway boundary is valid",
+			claims: Claims{NotBefore: now.Add(leeway).Unix()},
+		},
+		{
+			name:   "not-before just inside the leeway boundary is valid",
+			claims: Claims{NotBefore: now.Add(leeway - time.Second).Unix()},
+		},
+		{
+			name:    "not-before just outside the leeway boundary is not valid yet",
+			claims:  Claims{NotBefore: now.Add(leeway + time.Second).Unix()},
+			wantErr: ErrNotValidYet,
+		},
+		{
+			name:   "issued-at exactly on the leeway boundary is valid",
+			claims: Claims{IssuedAt: now.Add(leeway).Unix()},
+		},
+		{
+			name:   "issued-at just inside the leeway boundary is valid",
+			claims: Claims{IssuedAt: now.Add(leeway - time.Second).Unix()},
+		},
+		{
+			name:    "issued-at just outside the leeway boundary is issued in the future",
+			claims:  Claims{IssuedAt: now.Add(leeway + time.Second).Unix()},
+			wantErr: ErrIssuedInTheFuture,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateClaims(now, tt.claims, leeway); err != tt.wantErr {
+				t.Fatalf("validateClaims() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestWithLeeway_PropagatesPriorError ensures WithLeeway behaves like every
+// other TokenValidator in this package: it must never override an err it
+// did not itself produce. Chained after a validator like Expected, a
+// wrong-issuer rejection must survive WithLeeway's own (passing) time
+// check instead of being silently replaced by its nil return.
+func TestWithLeeway_PropagatesPriorError(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	Clock = func() time.Time { return now }
+	defer func() { Clock = time.Now }()
+
+	validClaims := Claims{Expiry: now.Add(time.Hour).Unix()}
+	priorErr := errors.New("jwt: unexpected issuer: https://wrong-issuer")
+
+	validator := WithLeeway(5 * time.Second)
+
+	if err := validator(nil, validClaims, priorErr); err != priorErr {
+		t.Fatalf("WithLeeway() = %v, want prior error %v preserved", err, priorErr)
+	}
+
+	for _, sentinel := range []error{nil, ErrExpired, ErrNotValidYet, ErrIssuedInTheFuture} {
+		if err := validator(nil, validClaims, sentinel); err != nil {
+			t.Fatalf("WithLeeway() with prior err %v = %v, want nil (valid claims)", sentinel, err)
+		}
+	}
+}
+
+// TestValidateClaims_ZeroLeeway is the exact, no-skew behavior the package
+// had before Leeway was introduced: it must be unaffected by this change.
+func TestValidateClaims_ZeroLeeway(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	if err := validateClaims(now, Claims{Expiry: now.Unix()}, 0); err != nil {
+		t.Fatalf("validateClaims() = %v, want nil", err)
+	}
+
+	if err := validateClaims(now, Claims{Expiry: now.Add(-time.Second).Unix()}, 0); err != ErrExpired {
+		t.Fatalf("validateClaims() = %v, want %v", err, ErrExpired)
+	}
+}
@@ -0,0 +1,162 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ClaimsV2 is the same standard claim set as `Claims`, except "nbf", "iat"
+// and "exp" are typed as `*NumericDate` instead of `int64`, so they survive
+// a round trip through tokens that carry fractional-second precision (RFC
+// 7519 permits this; the plain `int64` fields on `Claims` do not).
+//
+// ClaimsV2 implements `SignOption` by copying itself down into the `Claims`
+// the rest of the package (`Sign`, `Merge`, `validateClaims`) already knows
+// how to handle, so it is a drop-in replacement for `Claims` at call sites
+// without requiring any changes to the signing pipeline. Existing callers
+// that build tokens from `Claims` keep working unmodified; new callers that
+// need sub-second precision can build from `ClaimsV2` instead.
+//
+// That copy-down is one-way: `Verify` always decodes the signed payload
+// through the int64-truncating `Claims`/`validateClaims` path, so the
+// fractional precision ClaimsV2 preserves at Sign time is lost again
+// unless the verifying side also opts in. Chain `ValidateClaimsV2` onto
+// `Verify` to do that.
+type ClaimsV2 struct {
+	// See `Claims.NotBefore`.
+	NotBefore *NumericDate `json:"nbf,omitempty"`
+	// See `Claims.IssuedAt`.
+	IssuedAt *NumericDate `json:"iat,omitempty"`
+	// See `Claims.Expiry`.
+	Expiry *NumericDate `json:"exp,omitempty"`
+	// See `Claims.ID`.
+	ID string `json:"jti,omitempty"`
+	// See `Claims.Issuer`.
+	Issuer string `json:"iss,omitempty"`
+	// See `Claims.Subject`.
+	Subject string `json:"sub,omitempty"`
+	// See `Claims.Audience`.
+	Audience ClaimStrings `json:"aud,omitempty"`
+	// See `Claims.Nonce`.
+	Nonce string `json:"nonce,omitempty"`
+	// See `Claims.AuthorizedParty`.
+	AuthorizedParty string `json:"azp,omitempty"`
+}
+
+// ApplyClaims implements the `SignOption` interface. It copies ClaimsV2's
+// NumericDate-typed fields down into the int64-based Claims dest, trading
+// any sub-second precision for compatibility with the existing wire format.
+func (c ClaimsV2) ApplyClaims(dest *Claims) {
+	if v := c.NotBefore; v != nil {
+		dest.NotBefore = v.Unix()
+	}
+
+	if v := c.IssuedAt; v != nil {
+		dest.IssuedAt = v.Unix()
+	}
+
+	if v := c.Expiry; v != nil {
+		dest.Expiry = v.Unix()
+	}
+
+	if v := c.ID; v != "" {
+		dest.ID = v
+	}
+
+	if v := c.Issuer; v != "" {
+		dest.Issuer = v
+	}
+
+	if v := c.Subject; v != "" {
+		dest.Subject = v
+	}
+
+	if v := c.Audience; len(v) > 0 {
+		dest.Audience = v
+	}
+
+	if v := c.Nonce; v != "" {
+		dest.Nonce = v
+	}
+
+	if v := c.AuthorizedParty; v != "" {
+		dest.AuthorizedParty = v
+	}
+}
+
+// ToClaimsV2 copies c's fields into a ClaimsV2, converting the int64 epoch
+// seconds to NumericDate. It is the inverse of `ClaimsV2.ApplyClaims` and
+// lets existing `Claims` values be validated or re-signed through the
+// NumericDate-based path without a manual field-by-field copy.
+func (c Claims) ToClaimsV2() ClaimsV2 {
+	v2 := ClaimsV2{
+		ID:              c.ID,
+		Issuer:          c.Issuer,
+		Subject:         c.Subject,
+		Audience:        c.Audience,
+		Nonce:           c.Nonce,
+		AuthorizedParty: c.AuthorizedParty,
+	}
+
+	if c.NotBefore > 0 {
+		v2.NotBefore = NewNumericDate(time.Unix(c.NotBefore, 0))
+	}
+	if c.IssuedAt > 0 {
+		v2.IssuedAt = NewNumericDate(time.Unix(c.IssuedAt, 0))
+	}
+	if c.Expiry > 0 {
+		v2.Expiry = NewNumericDate(time.Unix(c.Expiry, 0))
+	}
+
+	return v2
+}
+
+// validateClaimsV2 is the ClaimsV2 counterpart of `validateClaims`, used by
+// callers that sign and verify through `ClaimsV2` directly and want to keep
+// sub-second precision for the comparisons too.
+func validateClaimsV2(t time.Time, claims ClaimsV2, leeway time.Duration) error {
+	return validateNumericClaims(t, claims.NotBefore, claims.IssuedAt, claims.Expiry, leeway)
+}
+
+// ValidateClaimsV2 returns a TokenValidator that re-decodes the token's raw
+// JSON payload into a ClaimsV2 (independently of the int64-truncating
+// Claims decode `Verify` already performed for standardClaims) and
+// validates "nbf", "iat" and "exp" through it instead, so a token signed
+// with sub-second precision actually has that precision consulted at
+// verification time. d is the leeway to apply, the same way `WithLeeway`'s
+// d is; like every other TokenValidator in this package it only ever
+// overrides err when err is nil or one of its own time sentinels, otherwise
+// propagating it unchanged.
+//
+// Usage:
+//
+//  token, _ := Sign(alg, key, ClaimsV2{Expiry: NewNumericDate(deadline)})
+//  _, err := Verify(alg, key, token, ValidateClaimsV2(5*time.Second))
+func ValidateClaimsV2(d time.Duration) TokenValidatorFunc {
+	return func(token []byte, standardClaims Claims, err error) error {
+		switch err {
+		case nil, ErrExpired, ErrNotValidYet, ErrIssuedInTheFuture:
+		default:
+			return err
+		}
+
+		parts := strings.SplitN(string(token), ".", 3)
+		if len(parts) != 3 {
+			return errors.New("jwt: invalid token")
+		}
+
+		payload, decodeErr := decodeSegment(parts[1])
+		if decodeErr != nil {
+			return decodeErr
+		}
+
+		var claimsV2 ClaimsV2
+		if jsonErr := json.Unmarshal(payload, &claimsV2); jsonErr != nil {
+			return jsonErr
+		}
+
+		return validateClaimsV2(Clock(), claimsV2, d)
+	}
+}
@@ -0,0 +1,104 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestValidateClaimsV2_Leeway mirrors TestValidateClaims_Leeway but exercises
+// sub-second boundaries that the int64-based validateClaims can't represent:
+// a NumericDate 100ms outside a 500ms leeway window must still fail, and
+// one 100ms inside it must still pass.
+func TestValidateClaimsV2_Leeway(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	leeway := 500 * time.Millisecond
+
+	tests := []struct {
+		name    string
+		claims  ClaimsV2
+		wantErr error
+	}{
+		{
+			name:   "expiry 400ms past now is within a 500ms leeway",
+			claims: ClaimsV2{Expiry: NewNumericDate(now.Add(-400 * time.Millisecond))},
+		},
+		{
+			name:    "expiry 600ms past now is outside a 500ms leeway",
+			claims:  ClaimsV2{Expiry: NewNumericDate(now.Add(-600 * time.Millisecond))},
+			wantErr: ErrExpired,
+		},
+		{
+			name:   "not-before 400ms ahead of now is within a 500ms leeway",
+			claims: ClaimsV2{NotBefore: NewNumericDate(now.Add(400 * time.Millisecond))},
+		},
+		{
+			name:    "not-before 600ms ahead of now is outside a 500ms leeway",
+			claims:  ClaimsV2{NotBefore: NewNumericDate(now.Add(600 * time.Millisecond))},
+			wantErr: ErrNotValidYet,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateClaimsV2(now, tt.claims, leeway); err != tt.wantErr {
+				t.Fatalf("validateClaimsV2() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestValidateClaimsV2_PropagatesPriorError checks ValidateClaimsV2 follows
+// the same TokenValidator contract as WithLeeway: a prior error unrelated to
+// timing must survive, not be overwritten by ValidateClaimsV2's own passing
+// check.
+func TestValidateClaimsV2_PropagatesPriorError(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	Clock = func() time.Time { return now }
+	defer func() { Clock = time.Now }()
+
+	token, err := Sign(HS256, []byte("secret"), ClaimsV2{Expiry: NewNumericDate(now.Add(time.Hour))})
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	priorErr := errors.New("jwt: unexpected issuer")
+	validator := ValidateClaimsV2(5 * time.Second)
+
+	if got := validator(token, Claims{}, priorErr); got != priorErr {
+		t.Fatalf("ValidateClaimsV2() = %v, want prior error %v preserved", got, priorErr)
+	}
+}
+
+// TestValidateClaimsV2_RoundTrip signs tokens via ClaimsV2 carrying
+// sub-second precision and checks ValidateClaimsV2 actually consults that
+// precision at verification time: an expiry 700ms ahead of now is accepted
+// and one 700ms behind it is rejected.
+func TestValidateClaimsV2_RoundTrip(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	Clock = func() time.Time { return now }
+	defer func() { Clock = time.Now }()
+
+	// Truncated to whole seconds, this expiry would equal now; kept at its
+	// real sub-second precision, it is still 700ms in the future.
+	expiry := now.Add(700 * time.Millisecond)
+
+	token, err := Sign(HS256, []byte("secret"), ClaimsV2{Expiry: NewNumericDate(expiry)})
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	if _, err := Verify(HS256, []byte("secret"), token, ValidateClaimsV2(0)); err != nil {
+		t.Fatalf("Verify() with ValidateClaimsV2 = %v, want nil (not yet expired at sub-second precision)", err)
+	}
+
+	past := NewNumericDate(now.Add(-700 * time.Millisecond))
+	expiredToken, err := Sign(HS256, []byte("secret"), ClaimsV2{Expiry: past})
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	if _, err := Verify(HS256, []byte("secret"), expiredToken, ValidateClaimsV2(0)); err != ErrExpired {
+		t.Fatalf("Verify() with ValidateClaimsV2 = %v, want %v", err, ErrExpired)
+	}
+}
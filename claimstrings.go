@@ -0,0 +1,48 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// ClaimStrings represents the "aud" claim type defined in RFC 7519: it may
+// be encoded as either a single JSON string or an array of strings, but is
+// always treated as a list once decoded. The plain `[]string` Claims used
+// before this type only round-trips the array form; tokens from issuers
+// that emit a bare string for a single audience would fail to unmarshal.
+type ClaimStrings []string
+
+// ClaimStringsAlwaysArray, when true, makes `ClaimStrings.MarshalJSON`
+// always emit a JSON array, even for a single value, instead of collapsing
+// it to a bare string. RFC 7519 allows both forms, but some relying
+// parties parse "aud" strictly as an array and reject the bare-string
+// form; set this once at startup to interoperate with them.
+var ClaimStringsAlwaysArray bool
+
+// MarshalJSON implements the json.Marshaler interface, encoding cs as a
+// bare JSON string when it holds exactly one value, and as a JSON array
+// otherwise. Set `ClaimStringsAlwaysArray` to always emit an array instead.
+func (cs ClaimStrings) MarshalJSON() ([]byte, error) {
+	if len(cs) == 1 && !ClaimStringsAlwaysArray {
+		return json.Marshal(cs[0])
+	}
+	return json.Marshal([]string(cs))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, accepting
+// either a JSON string or a JSON array of strings.
+func (cs *ClaimStrings) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*cs = ClaimStrings{s}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err == nil {
+		*cs = list
+		return nil
+	}
+
+	return errors.New("jwt: aud claim is neither a string nor an array of strings")
+}
@@ -0,0 +1,106 @@
+package jwt
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// TestClaimStrings_UnmarshalJSON exercises the two "aud" shapes real-world
+// issuers actually emit: Google's OIDC ID tokens use a bare string for a
+// single audience, while Cognito and Keycloak always use an array, even
+// with a single entry.
+func TestClaimStrings_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    ClaimStrings
+	}{
+		{
+			// https://developers.google.com/identity/openid-connect/openid-connect#obtainuserinfo
+			name:    "google ID token, single audience as a bare string",
+			payload: `{"aud":"407408718192.apps.googleusercontent.com"}`,
+			want:    ClaimStrings{"407408718192.apps.googleusercontent.com"},
+		},
+		{
+			// Cognito access/ID tokens list the app client id inside an
+			// array even when there is only one.
+			name:    "cognito token, single audience as a single-element array",
+			payload: `{"aud":["2gn9qr1mtssj0appclientid"]}`,
+			want:    ClaimStrings{"2gn9qr1mtssj0appclientid"},
+		},
+		{
+			// Keycloak tokens list every resource/client the token is
+			// valid for.
+			name:    "keycloak token, multiple audiences as an array",
+			payload: `{"aud":["account","my-api"]}`,
+			want:    ClaimStrings{"account", "my-api"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var claims Claims
+			if err := json.Unmarshal([]byte(tt.payload), &claims); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(claims.Audience, tt.want) {
+				t.Fatalf("Audience = %#v, want %#v", claims.Audience, tt.want)
+			}
+		})
+	}
+}
+
+// TestClaimStrings_MarshalJSON checks the interop-friendly encoding: a
+// single audience round-trips as a bare string, matching what Google's
+// tokens look like, and multiple audiences round-trip as an array.
+func TestClaimStrings_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		cs   ClaimStrings
+		want string
+	}{
+		{
+			name: "single audience marshals as a bare string",
+			cs:   ClaimStrings{"407408718192.apps.googleusercontent.com"},
+			want: `"407408718192.apps.googleusercontent.com"`,
+		},
+		{
+			name: "multiple audiences marshal as an array",
+			cs:   ClaimStrings{"account", "my-api"},
+			want: `["account","my-api"]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.cs)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+
+			if string(got) != tt.want {
+				t.Fatalf("json.Marshal() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestClaimStrings_MarshalJSON_AlwaysArray checks that ClaimStringsAlwaysArray
+// forces the array form even for a single audience, for relying parties
+// that reject the bare-string form despite RFC 7519 permitting it.
+func TestClaimStrings_MarshalJSON_AlwaysArray(t *testing.T) {
+	ClaimStringsAlwaysArray = true
+	defer func() { ClaimStringsAlwaysArray = false }()
+
+	got, err := json.Marshal(ClaimStrings{"407408718192.apps.googleusercontent.com"})
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	want := `["407408718192.apps.googleusercontent.com"]`
+	if string(got) != want {
+		t.Fatalf("json.Marshal() = %s, want %s", got, want)
+	}
+}
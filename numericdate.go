@@ -0,0 +1,72 @@
+package jwt
+
+import (
+	"strconv"
+	"time"
+)
+
+// NumericDate represents the NumericDate type defined in RFC 7519: a JSON
+// numeric value counting the seconds since the Unix epoch, UTC, which MAY
+// contain a non-integer (fractional-second) value. Wrapping `time.Time`
+// instead of `int64` lets the package round-trip that fractional precision
+// instead of truncating it, which is what happens today with the plain
+// `int64` fields on `Claims`.
+//
+// See `ClaimsV2` for the claim set that uses this type.
+type NumericDate struct {
+	time.Time
+}
+
+// NewNumericDate returns a NumericDate for t, matching the precision
+// `MarshalJSON` will actually emit (whole seconds plus up to nine decimal
+// digits of fraction).
+func NewNumericDate(t time.Time) *NumericDate {
+	return &NumericDate{Time: t}
+}
+
+// MarshalJSON implements the json.Marshaler interface, encoding the date as
+// a JSON number of seconds since the epoch, with a fractional part when the
+// wrapped time carries sub-second precision.
+func (d NumericDate) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+
+	seconds := d.Unix()
+	nanos := d.Nanosecond()
+	if nanos == 0 {
+		return []byte(strconv.FormatInt(seconds, 10)), nil
+	}
+
+	// strconv.FormatFloat can lose precision for large timestamps, so build
+	// the fractional representation from the integer parts directly.
+	frac := strconv.FormatInt(int64(nanos), 10)
+	for len(frac) < 9 {
+		frac = "0" + frac
+	}
+	for len(frac) > 0 && frac[len(frac)-1] == '0' {
+		frac = frac[:len(frac)-1]
+	}
+
+	return []byte(strconv.FormatInt(seconds, 10) + "." + frac), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, accepting any
+// JSON number (integer or float) and storing it as a UTC `time.Time`.
+func (d *NumericDate) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		*d = NumericDate{}
+		return nil
+	}
+
+	seconds, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
+
+	whole := int64(seconds)
+	frac := seconds - float64(whole)
+	*d = NumericDate{Time: time.Unix(whole, int64(frac*float64(time.Second))).UTC()}
+	return nil
+}
@@ -0,0 +1,383 @@
+package jwt
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Expected is a TokenValidator for verifying third-party OIDC ID tokens on
+// top of the standard claim checks already performed by `Verify`. It
+// enforces, in order: that "iss" equals Issuer (after URL-normalization,
+// compared case-sensitively as the spec requires), that at least one of the
+// token's "aud" values equals one of Audience, that "azp" is present and
+// matches when the token carries more than one audience, and that "nonce"
+// matches Nonce when one is set.
+//
+// Usage:
+//
+//  Verify(alg, key, idToken, Expected{
+//  	Issuer:   "https://accounts.google.com",
+//  	Audience: []string{clientID},
+//  	Nonce:    sessionNonce,
+//  })
+type Expected struct {
+	// Issuer is the OpenID Provider's issuer identifier, as published in its
+	// discovery document. Compared against the token's "iss" claim after
+	// both are URL-normalized (trailing slash insensitive), case-sensitively
+	// otherwise, per the OIDC Core spec.
+	Issuer string
+	// Audience must contain the relying party's client ID. The token is
+	// accepted if any of its "aud" values matches any entry here.
+	Audience []string
+	// Nonce, when non-empty, must equal the token's "nonce" claim. Leave
+	// empty to skip the check (e.g. for tokens obtained via the client
+	// credentials flow, which carry no nonce).
+	Nonce string
+	// AuthorizedParty, when non-empty, must equal the token's "azp" claim.
+	// Required by the OIDC Core spec whenever the token's "aud" lists more
+	// than one value; set explicitly here to also enforce it for
+	// single-audience tokens.
+	AuthorizedParty string
+}
+
+// ValidateToken implements the `TokenValidator` interface.
+func (e Expected) ValidateToken(token []byte, standardClaims Claims, err error) error {
+	if err != nil {
+		return err
+	}
+
+	if e.Issuer != "" && !sameIssuer(e.Issuer, standardClaims.Issuer) {
+		return fmt.Errorf("jwt: unexpected issuer: %s", standardClaims.Issuer)
+	}
+
+	if len(e.Audience) > 0 {
+		if !audienceMatches(e.Audience, standardClaims.Audience) {
+			return fmt.Errorf("jwt: unexpected audience: %v", standardClaims.Audience)
+		}
+
+		// Per the OIDC Core spec, "azp" must equal the relying party's own
+		// client ID, not merely some value from the accepted-audience list:
+		// Expected.Audience may legitimately list other parties' audiences
+		// too (multi-tenant/multi-resource setups), and accepting any of
+		// them as "azp" would let a token meant for a different party pass.
+		if len(standardClaims.Audience) > 1 && e.AuthorizedParty == "" {
+			return errors.New("jwt: Expected.AuthorizedParty must be set to validate azp on a multi-audience token")
+		}
+	}
+
+	if e.AuthorizedParty != "" && standardClaims.AuthorizedParty != e.AuthorizedParty {
+		return fmt.Errorf("jwt: unexpected azp: %s", standardClaims.AuthorizedParty)
+	}
+
+	if e.Nonce != "" && standardClaims.Nonce != e.Nonce {
+		return errors.New("jwt: nonce mismatch")
+	}
+
+	return nil
+}
+
+func sameIssuer(expected, actual string) bool {
+	return strings.TrimSuffix(expected, "/") == strings.TrimSuffix(actual, "/")
+}
+
+func audienceMatches(expected, actual []string) bool {
+	for _, a := range actual {
+		if contains(expected, a) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
+// openIDConfiguration is the subset of the OpenID Provider discovery
+// document (`/.well-known/openid-configuration`) that JWKS needs.
+type openIDConfiguration struct {
+	JWKSURI string `json:"jwks_uri"`
+}
+
+// jsonWebKey is the subset of RFC 7517 fields this package understands.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// publicKey converts the subset of RFC 7517 fields this package understands
+// (RSA only, for now) into a *rsa.PublicKey usable as a Verify key.
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	if k.Kty != "RSA" {
+		return nil, fmt.Errorf("jwt: jwks: unsupported key type: %s", k.Kty)
+	}
+
+	nBytes, err := decodeSegment(k.N)
+	if err != nil {
+		return nil, err
+	}
+
+	eBytes, err := decodeSegment(k.E)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: int(new(big.Int).SetBytes(eBytes).Int64()),
+	}, nil
+}
+
+// JWKS fetches and caches an OpenID Provider's signing keys, discovered from
+// its `/.well-known/openid-configuration` document, for use as the Keyfunc
+// of `VerifyWithKeyFunc`. It refreshes its cache on an unknown "kid" so that
+// provider key rotation does not require a restart.
+type JWKS struct {
+	issuerURL  string
+	httpClient *http.Client
+
+	mu   sync.RWMutex
+	keys map[string]interface{}
+}
+
+// NewRemoteJWKS discovers issuerURL's "jwks_uri" and fetches its keys.
+func NewRemoteJWKS(ctx context.Context, issuerURL string) (*JWKS, error) {
+	j := &JWKS{
+		issuerURL:  strings.TrimSuffix(issuerURL, "/"),
+		httpClient: http.DefaultClient,
+		keys:       make(map[string]interface{}),
+	}
+
+	if err := j.refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	return j, nil
+}
+
+func (j *JWKS) refresh(ctx context.Context) error {
+	jwksURI, err := j.discoverJWKSURI(ctx)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURI, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := j.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("jwt: jwks: unexpected status code: %d", resp.StatusCode)
+	}
+
+	var set jsonWebKeySet
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return err
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, key := range set.Keys {
+		pub, err := key.publicKey()
+		if err != nil {
+			continue // unsupported key type (e.g. EC, OKP); skip instead of failing the whole set.
+		}
+		keys[key.Kid] = pub
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.mu.Unlock()
+	return nil
+}
+
+func (j *JWKS) discoverJWKSURI(ctx context.Context) (string, error) {
+	if _, err := url.Parse(j.issuerURL); err != nil {
+		return "", fmt.Errorf("jwt: jwks: invalid issuer url: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.issuerURL+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := j.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("jwt: jwks: unexpected status code discovering jwks_uri: %d", resp.StatusCode)
+	}
+
+	var config openIDConfiguration
+	if err := json.NewDecoder(resp.Body).Decode(&config); err != nil {
+		return "", err
+	}
+
+	if config.JWKSURI == "" {
+		return "", errors.New("jwt: jwks: discovery document has no jwks_uri")
+	}
+
+	return config.JWKSURI, nil
+}
+
+// rsaAlgs is every Alg that JWKS's keys (RSA-only for now, see
+// jsonWebKey.publicKey) can legitimately be verified with. alg must always
+// be checked against an allow-list like this instead of being trusted from
+// the token itself: the signature algorithm has to come from the verifier's
+// own configuration (or, as here, from the key's actual type), never from
+// the unverified header of the token being verified, or a token could
+// retarget a known public key at a different algorithm family entirely
+// ("alg confusion").
+var rsaAlgs = map[Alg]bool{
+	RS256: true,
+	RS384: true,
+	RS512: true,
+	PS256: true,
+	PS384: true,
+	PS512: true,
+}
+
+// Keyfunc resolves kid to the matching public key, compatible with
+// `VerifyWithKeyFunc`. alg is checked against `rsaAlgs` before kid is even
+// looked up: JWKS only ever hands back RSA public keys, so any other alg is
+// rejected outright rather than being handed to `Verify` to check a
+// signature it was never meant to validate. On a cache miss it refreshes
+// once, in case the provider rotated its keys, before giving up.
+func (j *JWKS) Keyfunc(kid, alg string) (interface{}, error) {
+	if !rsaAlgs[Alg(alg)] {
+		return nil, fmt.Errorf("jwt: jwks: alg not valid for an RSA key: %s", alg)
+	}
+
+	if key, ok := j.lookup(kid); ok {
+		return key, nil
+	}
+
+	if err := j.refresh(context.Background()); err != nil {
+		return nil, err
+	}
+
+	if key, ok := j.lookup(kid); ok {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("jwt: jwks: unknown kid: %s", kid)
+}
+
+func (j *JWKS) lookup(kid string) (interface{}, bool) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	key, ok := j.keys[kid]
+	return key, ok
+}
+
+// VerifyWithKeyFunc verifies token the same way `Verify` does, except the
+// public key is resolved dynamically from the token's (unverified) "kid" and
+// "alg" header fields through keyfunc, instead of being supplied by the
+// caller up front. This is the entrypoint `JWKS.Keyfunc` is meant to be used
+// with, for providers that rotate signing keys.
+//
+// Because header.Alg comes from the token itself and is not yet trustworthy
+// at this point, VerifyWithKeyFunc double-checks it against the resolved
+// key's actual type with `keyMatchesAlg` before calling `Verify`, on top of
+// whatever allow-list keyfunc enforces internally (`JWKS.Keyfunc` has its
+// own). This closes the same "alg confusion" hole for any keyfunc, not only
+// JWKS's.
+func VerifyWithKeyFunc(token []byte, keyfunc func(kid, alg string) (interface{}, error), validators ...TokenValidator) (*VerifiedToken, error) {
+	header, err := unverifiedHeader(token)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := keyfunc(header.Kid, header.Alg)
+	if err != nil {
+		return nil, err
+	}
+
+	if !keyMatchesAlg(Alg(header.Alg), key) {
+		return nil, fmt.Errorf("jwt: alg confusion: %s does not match the resolved key", header.Alg)
+	}
+
+	return Verify(Alg(header.Alg), key, token, validators...)
+}
+
+// keyMatchesAlg reports whether alg is a plausible verification algorithm
+// for key's actual Go type. It is the last line of defense against "alg
+// confusion": a key resolved for one algorithm family (e.g. an RSA public
+// key fetched for RS256) must never be handed to `Verify` with a
+// token-supplied alg from a different family. Key types this package
+// doesn't recognize are left to `Verify`'s own alg/key checks.
+func keyMatchesAlg(alg Alg, key interface{}) bool {
+	switch key.(type) {
+	case *rsa.PublicKey:
+		return rsaAlgs[alg]
+	default:
+		return true
+	}
+}
+
+type tokenHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// unverifiedHeader decodes a JWT's header segment without checking its
+// signature, purely to learn which key to verify it with.
+func unverifiedHeader(token []byte) (tokenHeader, error) {
+	var header tokenHeader
+
+	parts := strings.SplitN(string(token), ".", 3)
+	if len(parts) != 3 {
+		return header, errors.New("jwt: invalid token")
+	}
+
+	raw, err := decodeSegment(parts[0])
+	if err != nil {
+		return header, err
+	}
+
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return header, err
+	}
+
+	return header, nil
+}
+
+// decodeSegment decodes a base64url JWT segment, tolerating both the
+// unpadded form mandated by RFC 7515 and a padded one, in case it is fed a
+// token produced by a more lenient encoder.
+func decodeSegment(s string) ([]byte, error) {
+	if b, err := base64.RawURLEncoding.DecodeString(s); err == nil {
+		return b, nil
+	}
+	return base64.URLEncoding.DecodeString(s)
+}
@@ -0,0 +1,236 @@
+package jwt
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestExpected_ValidateToken exercises the issuer/audience/azp/nonce checks
+// Expected performs on top of the standard claim validation, including the
+// multi-audience azp requirement called out in 7f52f2f.
+func TestExpected_ValidateToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		e       Expected
+		claims  Claims
+		wantErr bool
+	}{
+		{
+			name:   "matching issuer, trailing slash insensitive",
+			e:      Expected{Issuer: "https://issuer.example/"},
+			claims: Claims{Issuer: "https://issuer.example"},
+		},
+		{
+			name:    "wrong issuer is rejected",
+			e:       Expected{Issuer: "https://real-issuer.example"},
+			claims:  Claims{Issuer: "https://attacker.example"},
+			wantErr: true,
+		},
+		{
+			name:   "audience matches one of several accepted values",
+			e:      Expected{Audience: []string{"client-a", "client-b"}},
+			claims: Claims{Audience: ClaimStrings{"client-b"}},
+		},
+		{
+			name:    "audience matching none of the accepted values is rejected",
+			e:       Expected{Audience: []string{"client-a"}},
+			claims:  Claims{Audience: ClaimStrings{"client-c"}},
+			wantErr: true,
+		},
+		{
+			name:    "multi-audience token without AuthorizedParty set is rejected",
+			e:       Expected{Audience: []string{"client-a"}},
+			claims:  Claims{Audience: ClaimStrings{"client-a", "other-resource"}},
+			wantErr: true,
+		},
+		{
+			name:   "multi-audience token with matching azp is accepted",
+			e:      Expected{Audience: []string{"client-a"}, AuthorizedParty: "client-a"},
+			claims: Claims{Audience: ClaimStrings{"client-a", "other-resource"}, AuthorizedParty: "client-a"},
+		},
+		{
+			name:    "azp mismatch is rejected even for single-audience tokens",
+			e:       Expected{AuthorizedParty: "client-a"},
+			claims:  Claims{AuthorizedParty: "client-b"},
+			wantErr: true,
+		},
+		{
+			name:    "nonce mismatch is rejected",
+			e:       Expected{Nonce: "expected-nonce"},
+			claims:  Claims{Nonce: "replayed-nonce"},
+			wantErr: true,
+		},
+		{
+			name:   "nonce match is accepted",
+			e:      Expected{Nonce: "session-nonce"},
+			claims: Claims{Nonce: "session-nonce"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.e.ValidateToken(nil, tt.claims, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestExpected_ValidateToken_PropagatesPriorError checks Expected follows
+// the same TokenValidator contract as ExtractTopLevelClaims: a non-nil err
+// handed in, even a time sentinel such as ErrExpired that WithLeeway would
+// re-evaluate, is returned unchanged, never overwritten by a passing check
+// of its own.
+func TestExpected_ValidateToken_PropagatesPriorError(t *testing.T) {
+	wantErr := ErrExpired
+	e := Expected{Issuer: "https://issuer.example"}
+
+	if err := e.ValidateToken(nil, Claims{Issuer: "https://issuer.example"}, wantErr); err != wantErr {
+		t.Fatalf("ValidateToken() = %v, want %v propagated unchanged", err, wantErr)
+	}
+}
+
+func mustGenerateRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey() error = %v", err)
+	}
+	return key
+}
+
+func encodeRSAPublicKey(pub *rsa.PublicKey) (n, e string) {
+	n = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+	e = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	return n, e
+}
+
+// newJWKSServer serves a discovery document and a JWKS listing a single
+// RSA key under kid, backed by key.
+func newJWKSServer(t *testing.T, kid string, key *rsa.PrivateKey) *httptest.Server {
+	t.Helper()
+
+	var server *httptest.Server
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(openIDConfiguration{JWKSURI: server.URL + "/jwks.json"})
+	})
+
+	mux.HandleFunc("/jwks.json", func(w http.ResponseWriter, r *http.Request) {
+		n, e := encodeRSAPublicKey(&key.PublicKey)
+		json.NewEncoder(w).Encode(jsonWebKeySet{Keys: []jsonWebKey{
+			{Kty: "RSA", Kid: kid, N: n, E: e},
+		}})
+	})
+
+	server = httptest.NewServer(mux)
+	return server
+}
+
+// TestJWKS_Keyfunc_RejectsAlgConfusion checks that JWKS.Keyfunc rejects an
+// alg outside its RSA allow-list before ever resolving kid, closing the
+// "alg confusion" hole where a token's own (unverified) header could
+// otherwise retarget a published RSA public key at a different algorithm
+// family, e.g. treating it as an HS256 HMAC secret.
+func TestJWKS_Keyfunc_RejectsAlgConfusion(t *testing.T) {
+	key := mustGenerateRSAKey(t)
+	server := newJWKSServer(t, "key-1", key)
+	defer server.Close()
+
+	jwks, err := NewRemoteJWKS(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("NewRemoteJWKS() error = %v", err)
+	}
+
+	if _, err := jwks.Keyfunc("key-1", "HS256"); err == nil {
+		t.Fatal("Keyfunc() accepted HS256 against an RSA key, alg confusion is not blocked")
+	}
+
+	if _, err := jwks.Keyfunc("key-1", "RS256"); err != nil {
+		t.Fatalf("Keyfunc() rejected a valid RS256/RSA pairing: %v", err)
+	}
+}
+
+// TestKeyMatchesAlg checks the defense-in-depth guard VerifyWithKeyFunc
+// applies to any keyfunc's resolved key, independent of what that keyfunc
+// allow-lists internally.
+func TestKeyMatchesAlg(t *testing.T) {
+	key := mustGenerateRSAKey(t)
+
+	if !keyMatchesAlg(RS256, &key.PublicKey) {
+		t.Fatal("keyMatchesAlg(RS256, *rsa.PublicKey) = false, want true")
+	}
+
+	if keyMatchesAlg(HS256, &key.PublicKey) {
+		t.Fatal("keyMatchesAlg(HS256, *rsa.PublicKey) = true, want false (alg confusion)")
+	}
+
+	// Key types this package doesn't special-case (e.g. an HMAC secret) are
+	// left to Verify's own checks rather than rejected here.
+	if !keyMatchesAlg(HS256, []byte("secret")) {
+		t.Fatal("keyMatchesAlg(HS256, []byte) = false, want true (left to Verify)")
+	}
+}
+
+// TestJWKS_Keyfunc_RefreshesOnUnknownKid checks that a kid absent from the
+// cached key set triggers a refresh that picks up the rotated key, and that
+// a kid absent even after refreshing is rejected, covering provider key
+// rotation.
+func TestJWKS_Keyfunc_RefreshesOnUnknownKid(t *testing.T) {
+	oldKey := mustGenerateRSAKey(t)
+	newKey := mustGenerateRSAKey(t)
+
+	rotated := false
+	var server *httptest.Server
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(openIDConfiguration{JWKSURI: server.URL + "/jwks.json"})
+	})
+
+	mux.HandleFunc("/jwks.json", func(w http.ResponseWriter, r *http.Request) {
+		key, kid := oldKey, "key-1"
+		if rotated {
+			key, kid = newKey, "key-2"
+		}
+		n, e := encodeRSAPublicKey(&key.PublicKey)
+		json.NewEncoder(w).Encode(jsonWebKeySet{Keys: []jsonWebKey{
+			{Kty: "RSA", Kid: kid, N: n, E: e},
+		}})
+	})
+
+	server = httptest.NewServer(mux)
+	defer server.Close()
+
+	jwks, err := NewRemoteJWKS(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("NewRemoteJWKS() error = %v", err)
+	}
+
+	// The provider rotates its keys after the initial fetch; "key-2" is
+	// unknown to the cache until Keyfunc refreshes.
+	rotated = true
+
+	got, err := jwks.Keyfunc("key-2", "RS256")
+	if err != nil {
+		t.Fatalf("Keyfunc() did not refresh to pick up the rotated key: %v", err)
+	}
+
+	pub, ok := got.(*rsa.PublicKey)
+	if !ok || pub.N.Cmp(newKey.PublicKey.N) != 0 {
+		t.Fatal("Keyfunc() returned a key other than the rotated one")
+	}
+
+	if _, err := jwks.Keyfunc("no-such-kid", "RS256"); err == nil {
+		t.Fatal("Keyfunc() accepted a kid absent even after refresh")
+	}
+}
@@ -0,0 +1,185 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"time"
+)
+
+// accessTokenType and refreshTokenType are the "typ" values SignTokenPair
+// mirrors onto each half of a TokenPair via `TopLevelClaims`. VerifyAccessToken
+// and VerifyRefreshToken each require their own marker and reject the other
+// one, so a token signed as one can never be accepted where the other is
+// required, in either direction.
+const (
+	accessTokenType  = "access"
+	refreshTokenType = "refresh"
+)
+
+// ErrNotARefreshToken indicates that a token presented to VerifyRefreshToken
+// (or Rotate) is missing the "typ": "refresh" marker SignTokenPair stamps on
+// every refresh token, e.g. because an access token was passed by mistake.
+var ErrNotARefreshToken = errors.New("jwt: not a refresh token")
+
+// ErrNotAnAccessToken indicates that a token presented to VerifyAccessToken
+// is missing the "typ": "access" marker SignTokenPair stamps on every access
+// token, e.g. because a refresh token was passed by mistake. Guarding
+// against this, together with ErrNotARefreshToken on the other side, is what
+// keeps an access token and a refresh token from being accepted in each
+// other's place, even when both halves of a TokenPair share a signing key.
+var ErrNotAnAccessToken = errors.New("jwt: not an access token")
+
+// ErrRefreshTokenBlocked indicates that Rotate was called with a refresh
+// token whose "jti" is already on the caller's Blocklist, e.g. because it
+// was already rotated once before, or revoked out of band.
+var ErrRefreshTokenBlocked = errors.New("jwt: refresh token is blocked")
+
+// TokenPair is the result of `SignTokenPair`: an access token for calling
+// protected resources and a refresh token for minting a new pair once the
+// access token expires. Both share a "jti", so the refresh token can always
+// be traced back to the access token it was issued alongside.
+type TokenPair struct {
+	AccessToken  []byte
+	RefreshToken []byte
+}
+
+// Blocklist records refresh-token "jti" values that must no longer be
+// accepted, e.g. because they were already rotated once, or revoked out of
+// band (a logout, a detected compromise). Rotate consults and updates one
+// on every call; callers needing persistence across restarts back it with
+// whatever store they already use for revocation (Redis, a database table
+// with a TTL, ...).
+type Blocklist interface {
+	// Has reports whether jti has already been blocked.
+	Has(jti string) bool
+	// Block records jti as blocked until exp. Implementations may forget
+	// jti once exp has passed, since the token would fail `exp` validation
+	// by then regardless.
+	Block(jti string, exp time.Time) error
+}
+
+// SignTokenPair signs an access token and a refresh token from the same
+// claims, under accessKey and refreshKey respectively, linked by a shared
+// "jti". Each half additionally carries its own "typ" top-level claim (via
+// `TopLevelClaims`) — "access" or "refresh" — so VerifyAccessToken and
+// VerifyRefreshToken can each reject the other's token outright, even if a
+// caller mistakenly signs both halves with the same key.
+func SignTokenPair(alg Alg, accessKey, refreshKey PrivateKey, claims interface{}, accessMaxAge, refreshMaxAge time.Duration) (TokenPair, error) {
+	jti, err := newJTI()
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	withJTI := SignOptionFunc(func(c *Claims) {
+		c.ID = jti
+	})
+
+	markAccess, err := TopLevelClaims([]string{"typ"}, map[string]interface{}{"typ": accessTokenType})
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	accessToken, err := Sign(alg, accessKey, claims, MaxAge(accessMaxAge), withJTI, markAccess)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	markRefresh, err := TopLevelClaims([]string{"typ"}, map[string]interface{}{"typ": refreshTokenType})
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	refreshToken, err := Sign(alg, refreshKey, claims, MaxAge(refreshMaxAge), withJTI, markRefresh)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	return TokenPair{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+// VerifyAccessToken verifies token the same way `Verify` does, and
+// additionally rejects it with ErrNotAnAccessToken unless it carries the
+// "typ": "access" top-level claim `SignTokenPair` stamps on every access
+// token it issues, so a refresh token — or any other token that merely
+// lacks the marker, e.g. one signed directly with `Sign` — can never be
+// accepted where an access token is required.
+func VerifyAccessToken(alg Alg, key PublicKey, token []byte, validators ...TokenValidator) (*VerifiedToken, error) {
+	var typ struct {
+		Type string `json:"typ"`
+	}
+
+	all := append(append([]TokenValidator{}, validators...), ExtractTopLevelClaims(&typ))
+
+	verified, err := Verify(alg, key, token, all...)
+	if err != nil {
+		return nil, err
+	}
+
+	if typ.Type != accessTokenType {
+		return nil, ErrNotAnAccessToken
+	}
+
+	return verified, nil
+}
+
+// VerifyRefreshToken verifies token the same way `Verify` does, and
+// additionally rejects it with ErrNotARefreshToken unless it carries the
+// "typ": "refresh" top-level claim `SignTokenPair` stamps on every refresh
+// token it issues, so an access token can never be accepted where a refresh
+// token is required.
+func VerifyRefreshToken(alg Alg, key PublicKey, token []byte, validators ...TokenValidator) (*VerifiedToken, error) {
+	var typ struct {
+		Type string `json:"typ"`
+	}
+
+	all := append(append([]TokenValidator{}, validators...), ExtractTopLevelClaims(&typ))
+
+	verified, err := Verify(alg, key, token, all...)
+	if err != nil {
+		return nil, err
+	}
+
+	if typ.Type != refreshTokenType {
+		return nil, ErrNotARefreshToken
+	}
+
+	return verified, nil
+}
+
+// Rotate verifies refreshToken against refreshPublicKey (the public half of
+// refreshKey; the two differ for every asymmetric alg, so Rotate cannot
+// derive one from the other), rejects it if its "jti" is already on
+// blocklist (defense against reusing a stolen or already-rotated refresh
+// token), records that jti as blocked, and issues a fresh TokenPair in its
+// place, signed for accessMaxAge and refreshMaxAge. The new pair carries the
+// registered claims (the fields of `Claims`) decoded from refreshToken; any
+// other claims refreshToken carries are not copied over.
+func Rotate(alg Alg, accessKey, refreshKey PrivateKey, refreshPublicKey PublicKey, refreshToken []byte, accessMaxAge, refreshMaxAge time.Duration, blocklist Blocklist) (TokenPair, error) {
+	var claims Claims
+
+	if _, err := VerifyRefreshToken(alg, refreshPublicKey, refreshToken, ExtractTopLevelClaims(&claims)); err != nil {
+		return TokenPair{}, err
+	}
+
+	if blocklist.Has(claims.ID) {
+		return TokenPair{}, ErrRefreshTokenBlocked
+	}
+
+	if err := blocklist.Block(claims.ID, time.Unix(claims.Expiry, 0)); err != nil {
+		return TokenPair{}, err
+	}
+
+	return SignTokenPair(alg, accessKey, refreshKey, claims, accessMaxAge, refreshMaxAge)
+}
+
+// newJTI returns a random, URL-safe "jti" value, unique enough to link an
+// access token to the refresh token it was issued alongside and to serve as
+// a Blocklist key.
+func newJTI() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
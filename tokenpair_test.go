@@ -0,0 +1,106 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+)
+
+type mapBlocklist map[string]time.Time
+
+func (m mapBlocklist) Has(jti string) bool {
+	_, ok := m[jti]
+	return ok
+}
+
+func (m mapBlocklist) Block(jti string, exp time.Time) error {
+	m[jti] = exp
+	return nil
+}
+
+// TestTokenPair_TypeGuardsHoldEvenWithSharedKey checks that the "typ"
+// marker, not key separation, is what keeps an access token and a refresh
+// token from being accepted in each other's place — the bug fixed in
+// 8cd7787, where access tokens carried no marker at all.
+func TestTokenPair_TypeGuardsHoldEvenWithSharedKey(t *testing.T) {
+	secret := []byte("shared-secret")
+
+	pair, err := SignTokenPair(HS256, secret, secret, Claims{Subject: "user-1"}, 15*time.Minute, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("SignTokenPair() error = %v", err)
+	}
+
+	if _, err := VerifyRefreshToken(HS256, secret, pair.AccessToken); err != ErrNotARefreshToken {
+		t.Fatalf("VerifyRefreshToken(access token) error = %v, want %v", err, ErrNotARefreshToken)
+	}
+
+	if _, err := VerifyAccessToken(HS256, secret, pair.RefreshToken); err != ErrNotAnAccessToken {
+		t.Fatalf("VerifyAccessToken(refresh token) error = %v, want %v", err, ErrNotAnAccessToken)
+	}
+
+	if _, err := VerifyAccessToken(HS256, secret, pair.AccessToken); err != nil {
+		t.Fatalf("VerifyAccessToken(access token) error = %v, want nil", err)
+	}
+
+	if _, err := VerifyRefreshToken(HS256, secret, pair.RefreshToken); err != nil {
+		t.Fatalf("VerifyRefreshToken(refresh token) error = %v, want nil", err)
+	}
+}
+
+// TestVerifyAccessToken_RejectsTypelessToken checks that VerifyAccessToken
+// requires its own "typ": "access" marker rather than merely rejecting the
+// refresh marker, so a plain token signed with `Sign` directly — which
+// carries no "typ" claim at all — can never be accepted as an access token.
+func TestVerifyAccessToken_RejectsTypelessToken(t *testing.T) {
+	secret := []byte("shared-secret")
+
+	token, err := Sign(HS256, secret, Claims{Subject: "user-1"})
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	if _, err := VerifyAccessToken(HS256, secret, token); err != ErrNotAnAccessToken {
+		t.Fatalf("VerifyAccessToken(typeless token) error = %v, want %v", err, ErrNotAnAccessToken)
+	}
+}
+
+// TestRotate_BlocksReuseOfRotatedToken checks that Rotate consults the
+// Blocklist it is handed and refuses a refresh token already recorded on
+// it, e.g. because it was already rotated once.
+func TestRotate_BlocksReuseOfRotatedToken(t *testing.T) {
+	accessKey := []byte("access-secret")
+	refreshKey := []byte("refresh-secret")
+
+	pair, err := SignTokenPair(HS256, accessKey, refreshKey, Claims{Subject: "user-1"}, 15*time.Minute, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("SignTokenPair() error = %v", err)
+	}
+
+	blocklist := mapBlocklist{}
+
+	if _, err := Rotate(HS256, accessKey, refreshKey, refreshKey, pair.RefreshToken, 15*time.Minute, 24*time.Hour, blocklist); err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+
+	if _, err := Rotate(HS256, accessKey, refreshKey, refreshKey, pair.RefreshToken, 15*time.Minute, 24*time.Hour, blocklist); err != ErrRefreshTokenBlocked {
+		t.Fatalf("Rotate() reused a blocked refresh token, error = %v, want %v", err, ErrRefreshTokenBlocked)
+	}
+}
+
+// TestRotate_RejectsWrongPublicKey checks that Rotate actually verifies
+// against the refreshPublicKey it was given, instead of (as in the bug
+// fixed in 8cd7787) the refresh signing key — a wrong public key must fail
+// verification rather than rotate anyway.
+func TestRotate_RejectsWrongPublicKey(t *testing.T) {
+	accessKey := []byte("access-secret")
+	refreshKey := []byte("refresh-secret")
+	wrongPublicKey := []byte("not-the-refresh-secret")
+
+	pair, err := SignTokenPair(HS256, accessKey, refreshKey, Claims{Subject: "user-1"}, 15*time.Minute, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("SignTokenPair() error = %v", err)
+	}
+
+	if _, err := Rotate(HS256, accessKey, refreshKey, wrongPublicKey, pair.RefreshToken, 15*time.Minute, 24*time.Hour, mapBlocklist{}); err == nil {
+		t.Fatal("Rotate() accepted a refresh token verified against the wrong public key")
+	}
+}
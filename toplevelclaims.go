@@ -0,0 +1,108 @@
+package jwt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// registeredClaimNames are the JSON keys the fields of `Claims` already
+// occupy. `TopLevelClaims` refuses to mirror a custom claim under any of
+// these names so it can never shadow a registered claim.
+var registeredClaimNames = map[string]bool{
+	"nbf":   true,
+	"iat":   true,
+	"exp":   true,
+	"jti":   true,
+	"iss":   true,
+	"sub":   true,
+	"aud":   true,
+	"nonce": true,
+	"azp":   true,
+}
+
+// TopLevelClaims returns a SignOption that mirrors the keys of source
+// listed in allowed onto the top-level JSON object `Sign` produces,
+// alongside the registered claims ("iss", "exp", ...), instead of nesting
+// them under a sub-object. This is the shape services like Hydra/fosite
+// expect for session extras such as roles, tenant or scope.
+//
+// It returns an error immediately, rather than failing silently at Sign
+// time, if source contains a key that is not in allowed or that collides
+// with a registered claim name.
+//
+// Pair it with `ExtractTopLevelClaims` on the verifying side to read the
+// mirrored claims back out.
+func TopLevelClaims(allowed []string, source map[string]interface{}) (SignOptionFunc, error) {
+	allow := make(map[string]bool, len(allowed))
+	for _, k := range allowed {
+		allow[k] = true
+	}
+
+	extra := make(map[string]interface{}, len(source))
+	for k, v := range source {
+		if registeredClaimNames[k] {
+			return nil, fmt.Errorf("jwt: claim %q is a registered claim and cannot be mirrored to the top level", k)
+		}
+
+		if !allow[k] {
+			return nil, fmt.Errorf("jwt: claim %q is not in the allow-list", k)
+		}
+
+		extra[k] = v
+	}
+
+	return func(c *Claims) {
+		c.extra = extra
+	}, nil
+}
+
+// ExtractTopLevelClaims returns a TokenValidator that decodes the token's
+// JSON payload into dest (a pointer to a map[string]interface{} or a
+// struct), the complement of `TopLevelClaims` on the verifying side. dest
+// also receives the registered claim fields, since they live in the same
+// top-level object; callers that only want the custom ones can ignore the
+// rest or unmarshal into a struct that only declares the fields it needs.
+func ExtractTopLevelClaims(dest interface{}) TokenValidatorFunc {
+	return func(token []byte, standardClaims Claims, err error) error {
+		if err != nil {
+			return err
+		}
+
+		parts := strings.SplitN(string(token), ".", 3)
+		if len(parts) != 3 {
+			return errors.New("jwt: invalid token")
+		}
+
+		payload, decodeErr := decodeSegment(parts[1])
+		if decodeErr != nil {
+			return decodeErr
+		}
+
+		return json.Unmarshal(payload, dest)
+	}
+}
+
+// mergeJSONObjects flattens two marshaled JSON objects into one, the same
+// way `Merge` does, except it also tolerates either side being empty
+// ("{}"), which `Merge` does not: `Claims.MarshalJSON` hits that case
+// whenever no registered claims are set but custom ones are.
+func mergeJSONObjects(a, b []byte) []byte {
+	a = bytes.TrimSpace(a)
+	b = bytes.TrimSpace(b)
+
+	if len(a) <= 2 {
+		return b
+	}
+	if len(b) <= 2 {
+		return a
+	}
+
+	merged := make([]byte, 0, len(a)+len(b))
+	merged = append(merged, a[:len(a)-1]...)
+	merged = append(merged, ',')
+	merged = append(merged, b[1:]...)
+	return merged
+}
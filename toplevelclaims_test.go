@@ -0,0 +1,47 @@
+package jwt
+
+import "testing"
+
+func TestTopLevelClaims_RejectsKeyNotInAllowList(t *testing.T) {
+	_, err := TopLevelClaims([]string{"role"}, map[string]interface{}{"tenant": "acme"})
+	if err == nil {
+		t.Fatal("TopLevelClaims() did not reject a key outside the allow-list")
+	}
+}
+
+func TestTopLevelClaims_RejectsRegisteredClaimName(t *testing.T) {
+	_, err := TopLevelClaims([]string{"iss"}, map[string]interface{}{"iss": "attacker"})
+	if err == nil {
+		t.Fatal("TopLevelClaims() did not reject a registered claim name")
+	}
+}
+
+// TestTopLevelClaims_RoundTrip signs a token with custom claims mirrored to
+// the top level and checks ExtractTopLevelClaims reads them back out.
+func TestTopLevelClaims_RoundTrip(t *testing.T) {
+	opt, err := TopLevelClaims([]string{"role", "tenant"}, map[string]interface{}{
+		"role":   "admin",
+		"tenant": "acme",
+	})
+	if err != nil {
+		t.Fatalf("TopLevelClaims() error = %v", err)
+	}
+
+	token, err := Sign(HS256, []byte("secret"), Claims{Subject: "user-1"}, opt)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	var extra struct {
+		Role   string `json:"role"`
+		Tenant string `json:"tenant"`
+	}
+
+	if _, err := Verify(HS256, []byte("secret"), token, ExtractTopLevelClaims(&extra)); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	if extra.Role != "admin" || extra.Tenant != "acme" {
+		t.Fatalf("extracted claims = %+v, want role=admin tenant=acme", extra)
+	}
+}